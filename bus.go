@@ -0,0 +1,223 @@
+package i2c
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"time"
+	"unsafe"
+)
+
+// Bus owns a single /dev/i2c-N file descriptor and serializes access to
+// it with an internal mutex. Unlike Options, which binds one slave
+// address to the file descriptor for its whole lifetime via I2C_SLAVE,
+// a Bus carries no sticky address: each Device obtained from it submits
+// its own address on every transaction, in the i2c_msg of an I2C_RDWR
+// ioctl or, for native SMBus transactions, by re-attaching it with
+// I2C_SLAVE while holding the Bus mutex, so goroutines driving different
+// chips on the same bus cannot race each other's I2C_SLAVE state.
+type Bus struct {
+	dev string
+	rc  *os.File
+	mu  sync.Mutex
+}
+
+// NewBus opens dev (e.g. "/dev/i2c-1") for concurrent use by one or more
+// Devices.
+func NewBus(dev string) (*Bus, error) {
+
+	f, err := os.OpenFile(dev, os.O_RDWR, 0600)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &Bus{
+		dev: dev,
+		rc:  f,
+	}, nil
+}
+
+// GetDev returns the full device name the Bus was opened with.
+func (b *Bus) GetDev() string {
+	return b.dev
+}
+
+// Device returns a handle for the slave at addr. addr is a uint16 so
+// 10-bit addresses (0x000-0x3FF) can be represented; ordinary 7-bit
+// devices just use the low 7 bits. Device is cheap to create and safe to
+// share across goroutines; the Bus it belongs to is what provides the
+// actual locking.
+func (b *Bus) Device(addr uint16) *Device {
+	return &Device{
+		bus:  b,
+		addr: addr,
+	}
+}
+
+// Close closes the underlying file descriptor. Any Device created from
+// this Bus becomes unusable afterwards.
+func (b *Bus) Close() error {
+	return b.rc.Close()
+}
+
+// SetTimeout bounds how long the adapter waits for a transaction to
+// complete before giving up, via I2C_TIMEOUT. The kernel expresses this
+// in jiffies of 10ms each, so d is rounded down to the nearest 10ms.
+func (b *Bus) SetTimeout(d time.Duration) error {
+	return ioctl(b.rc.Fd(), I2C_TIMEOUT, uintptr(d/(10*time.Millisecond)))
+}
+
+// SetRetries sets how many times the adapter retries a transaction that
+// receives no ACK, via I2C_RETRIES.
+func (b *Bus) SetRetries(n int) error {
+	return ioctl(b.rc.Fd(), I2C_RETRIES, uintptr(n))
+}
+
+// tx submits msgs as a single I2C_RDWR ioctl, holding the Bus mutex for
+// the duration so no other Device interleaves a transaction on the wire.
+func (b *Bus) tx(msgs []i2c_msg) error {
+
+	data := i2c_rdwr_ioctl_data{
+		msgs:  uintptr(unsafe.Pointer(&msgs[0])),
+		nmsgs: uint32(len(msgs)),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return ioctl(b.rc.Fd(), I2C_RDWR, uintptr(unsafe.Pointer(&data)))
+}
+
+// Device is a handle to a single slave address on a Bus. It carries no
+// state of its own beyond the address and the SetTenBit/SetForce
+// settings, which are guarded by the Bus mutex, so it is safe to keep one per
+// chip and call its methods concurrently with other Devices on the same
+// Bus.
+type Device struct {
+	bus         *Bus
+	addr        uint16
+	tenBit      bool
+	forceAttach bool
+}
+
+// GetAddr returns the slave address this Device talks to.
+func (d *Device) GetAddr() uint16 {
+	return d.addr
+}
+
+// Bus returns the Bus this Device was created from.
+func (d *Device) Bus() *Bus {
+	return d.bus
+}
+
+// SetTenBit marks this Device's address as a 10-bit address, so the
+// messages ReadBytes/WriteBytes/WriteThenReadBytes and the SMBus
+// emulation submit carry I2C_M_TEN; Tx segments use their own TenBit
+// field instead. SetTenBit issues no ioctl itself: smbusIoctl sets
+// I2C_TENBIT on the bus fd to match before each native SMBus
+// transaction. tenBit is guarded by the owning Bus's mutex, the same lock
+// ReadBytes/WriteBytes/smbusIoctl take, so SetTenBit is safe to call
+// concurrently with transactions on this Device.
+func (d *Device) SetTenBit(enable bool) {
+	d.bus.mu.Lock()
+	defer d.bus.mu.Unlock()
+	d.tenBit = enable
+}
+
+// SetForce marks this Device as needing I2C_SLAVE_FORCE instead of
+// I2C_SLAVE when an SMBus transaction attaches its address on the bus
+// fd, so SMBusXxx calls on a Device obtained through Options opened via
+// NewForce don't fail with EBUSY the way a plain I2C_SLAVE attach would.
+// It has no effect on ReadBytes/WriteBytes/WriteThenReadBytes/Tx, which
+// address over I2C_RDWR and never attach via I2C_SLAVE at all.
+// forceAttach is guarded the same way tenBit is, see SetTenBit.
+func (d *Device) SetForce(enable bool) {
+	d.bus.mu.Lock()
+	defer d.bus.mu.Unlock()
+	d.forceAttach = enable
+}
+
+// flags returns the base i2c_msg flags for this Device, i.e. I2C_M_TEN
+// when it was marked 10-bit via SetTenBit.
+func (d *Device) flags() uint16 {
+
+	d.bus.mu.Lock()
+	defer d.bus.mu.Unlock()
+
+	if d.tenBit {
+		return I2C_M_TEN
+	}
+
+	return 0
+}
+
+// msg builds an i2c_msg for buf. buf must not be empty: indexing buf[0]
+// to take its address would panic on a zero-length slice, so callers
+// check len(buf) first, the same guard Tx applies to every segment.
+func (d *Device) msg(flags uint16, buf []byte) i2c_msg {
+	return i2c_msg{
+		addr:  d.addr,
+		flags: flags,
+		len:   uint16(len(buf)),
+		buf:   uintptr(unsafe.Pointer(&buf[0])),
+	}
+}
+
+// ReadBytes reads bytes from the device. Number of bytes read
+// corresponds to buf parameter length.
+func (d *Device) ReadBytes(buf []byte) (int, error) {
+
+	if len(buf) == 0 {
+		return 0, errors.New("i2c: ReadBytes called with an empty buffer")
+	}
+
+	msgs := []i2c_msg{d.msg(d.flags()|I2C_M_RD, buf)}
+
+	if err := d.bus.tx(msgs); err != nil {
+		return 0, err
+	}
+
+	return len(buf), nil
+}
+
+// WriteBytes sends bytes to the device. The interpretation of the
+// message is implementation-dependent.
+func (d *Device) WriteBytes(buf []byte) (int, error) {
+
+	if len(buf) == 0 {
+		return 0, errors.New("i2c: WriteBytes called with an empty buffer")
+	}
+
+	msgs := []i2c_msg{d.msg(d.flags(), buf)}
+
+	if err := d.bus.tx(msgs); err != nil {
+		return 0, err
+	}
+
+	return len(buf), nil
+}
+
+// WriteThenReadBytes sends two I2C messages, the first to write some
+// bytes then the second to read them, without a STOP condition between
+// the two.
+func (d *Device) WriteThenReadBytes(writeBuf, readBuf []byte) (int, int, error) {
+
+	if len(writeBuf) == 0 {
+		return 0, 0, errors.New("i2c: WriteThenReadBytes called with an empty write buffer")
+	}
+	if len(readBuf) == 0 {
+		return 0, 0, errors.New("i2c: WriteThenReadBytes called with an empty read buffer")
+	}
+
+	msgs := []i2c_msg{
+		d.msg(d.flags(), writeBuf),
+		d.msg(d.flags()|I2C_M_RD, readBuf),
+	}
+
+	if err := d.bus.tx(msgs); err != nil {
+		return 0, 0, err
+	}
+
+	return len(writeBuf), len(readBuf), nil
+}
@@ -0,0 +1,100 @@
+package i2c
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Adapter describes one I2C adapter discovered by Enumerate.
+type Adapter struct {
+	Dev   string // full device path, e.g. "/dev/i2c-1"
+	Name  string // adapter name from the "name" sysfs attribute
+	Funcs uint64 // I2C_FUNCS functionality bitmask, e.g. I2C_FUNC_I2C, I2C_FUNC_SMBUS_*
+}
+
+// Enumerate walks /sys/class/i2c-dev to list the I2C adapters available
+// on this system, the Go equivalent of `i2cdetect -l`. Adapters that
+// cannot be opened (e.g. due to permissions) are silently skipped.
+func Enumerate() ([]Adapter, error) {
+
+	const sysClass = "/sys/class/i2c-dev"
+
+	entries, err := os.ReadDir(sysClass)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var adapters []Adapter
+
+	for _, e := range entries {
+
+		name, err := os.ReadFile(filepath.Join(sysClass, e.Name(), "name"))
+
+		if err != nil {
+			continue
+		}
+
+		dev := filepath.Join("/dev", e.Name())
+
+		bus, err := NewBus(dev)
+
+		if err != nil {
+			continue
+		}
+
+		funcs, err := bus.Funcs()
+		bus.Close()
+
+		if err != nil {
+			continue
+		}
+
+		adapters = append(adapters, Adapter{
+			Dev:   dev,
+			Name:  strings.TrimSpace(string(name)),
+			Funcs: funcs,
+		})
+	}
+
+	return adapters, nil
+}
+
+// Scan probes addresses 0x03-0x77 on bus and returns those that ACK, the
+// Go equivalent of `i2cdetect -y <bus>`. Where the adapter reports
+// I2C_FUNC_SMBUS_QUICK it probes with an SMBus quick write, which is
+// safe for nearly every device; otherwise it falls back to a 1-byte
+// read, which can upset a handful of write-only devices but is the best
+// available probe without native SMBus support.
+func Scan(bus *Bus) ([]uint8, error) {
+
+	funcs, err := bus.Funcs()
+
+	if err != nil {
+		return nil, err
+	}
+
+	quick := funcs&I2C_FUNC_SMBUS_QUICK != 0
+
+	var found []uint8
+
+	for addr := uint8(0x03); addr <= 0x77; addr++ {
+
+		dev := bus.Device(uint16(addr))
+
+		var probeErr error
+
+		if quick {
+			probeErr = dev.SMBusQuick(true)
+		} else {
+			_, probeErr = dev.ReadBytes(make([]byte, 1))
+		}
+
+		if probeErr == nil {
+			found = append(found, addr)
+		}
+	}
+
+	return found, nil
+}
@@ -9,16 +9,22 @@
 package i2c
 
 import (
-	"os"
 	"syscall"
-	"unsafe"
 )
 
 // Options represents a connection to I2C-device.
+//
+// Options binds a single slave address to the underlying bus for its
+// whole lifetime, which is convenient for the common case of one chip
+// per bus but unsafe to share across goroutines driving different
+// addresses. For that, open a Bus directly and obtain one Device per
+// chip instead.
 type Options struct {
-	addr uint8
-	dev  string
-	rc   *os.File
+	addr   uint8
+	addr16 uint16
+	dev    string
+	bus    *Bus
+	device *Device
 }
 
 // i2c_msg struct represents an I2C message
@@ -41,31 +47,92 @@ type i2c_rdwr_ioctl_data struct {
 // register address to read from, either write register
 // together with the data in case of write operations.
 func New(addr uint8, dev string) (*Options, error) {
+	return newOptions(addr, dev, I2C_SLAVE)
+}
+
+// NewForce opens a connection for I2C-device like New, but attaches via
+// I2C_SLAVE_FORCE instead of I2C_SLAVE, so addr is claimed even if the
+// kernel considers it busy (e.g. an in-kernel driver is already bound to
+// it). Use with care: it bypasses the kernel's own collision check.
+func NewForce(addr uint8, dev string) (*Options, error) {
+	return newOptions(addr, dev, I2C_SLAVE_FORCE)
+}
+
+func newOptions(addr uint8, dev string, attachCmd uintptr) (*Options, error) {
+
+	i2c := &Options{
+		addr:   addr,
+		addr16: uint16(addr),
+		dev:    dev,
+	}
+
+	bus, err := NewBus(dev)
+
+	if err != nil {
+		return i2c, err
+	}
+
+	if err := ioctl(bus.rc.Fd(), attachCmd, uintptr(addr)); err != nil {
+		return i2c, err
+	}
+
+	i2c.bus = bus
+	i2c.device = bus.Device(uint16(addr))
+	i2c.device.SetForce(attachCmd == I2C_SLAVE_FORCE)
+	return i2c, nil
+}
+
+// NewTenBit opens a connection for a 10-bit I2C-device, the Options
+// counterpart to Bus.Device+Device.SetTenBit. Unlike New/NewForce, addr
+// is a uint16 so the full 10-bit range (0x000-0x3FF) can be represented.
+func NewTenBit(addr uint16, dev string) (*Options, error) {
 
 	i2c := &Options{
-		addr: addr,
-		dev:  dev,
+		addr:   uint8(addr),
+		addr16: addr,
+		dev:    dev,
 	}
 
-	f, err := os.OpenFile(dev, os.O_RDWR, 0600)
+	bus, err := NewBus(dev)
 
 	if err != nil {
 		return i2c, err
 	}
 
-	if err := ioctl(f.Fd(), I2C_SLAVE, uintptr(addr)); err != nil {
+	if err := ioctl(bus.rc.Fd(), I2C_TENBIT, 1); err != nil {
 		return i2c, err
 	}
 
-	i2c.rc = f
+	if err := ioctl(bus.rc.Fd(), I2C_SLAVE, uintptr(addr)); err != nil {
+		return i2c, err
+	}
+
+	i2c.bus = bus
+	i2c.device = bus.Device(addr)
+	i2c.device.SetTenBit(true)
 	return i2c, nil
 }
 
-// GetAddr return device occupied address in the bus.
+// GetAddr return device occupied address in the bus. For a Device opened
+// via NewTenBit with an address above 0xFF, this truncates to the low 8
+// bits; use GetAddr16 to recover the full address.
 func (o *Options) GetAddr() uint8 {
 	return o.addr
 }
 
+// GetAddr16 returns the full slave address this Options talks to,
+// including the upper bits of a 10-bit address that GetAddr truncates.
+func (o *Options) GetAddr16() uint16 {
+	return o.addr16
+}
+
+// SetTenBit marks this Options' address as a 10-bit address; see
+// Device.SetTenBit. New/NewForce callers addressing a device above 0x7F
+// should use NewTenBit instead, which sets this automatically.
+func (o *Options) SetTenBit(enable bool) {
+	o.device.SetTenBit(enable)
+}
+
 // GetDev return full device name.
 func (o *Options) GetDev() string {
 	return o.dev
@@ -76,14 +143,7 @@ func (o *Options) GetDev() string {
 // ReadBytes read bytes from I2C-device.
 // Number of bytes read correspond to buf parameter length.
 func (o *Options) ReadBytes(buf []byte) (int, error) {
-
-	n, err := o.rc.Read(buf)
-
-	if err != nil {
-		return n, err
-	}
-
-	return n, nil
+	return o.device.ReadBytes(buf)
 }
 
 // ReadRegBytes read count of n byte's sequence from I2C-device
@@ -215,7 +275,7 @@ func (o *Options) ReadRegU32BE(reg byte) (uint32, error) {
 // WriteBytes send bytes to the remote I2C-device. The interpretation of
 // the message is implementation-dependent.
 func (o *Options) WriteBytes(buf []byte) (int, error) {
-	return o.rc.Write(buf)
+	return o.device.WriteBytes(buf)
 }
 
 // WriteRegBytes send bytes to the remote I2C-device starting from reg address.
@@ -307,37 +367,89 @@ func (v *Options) WriteRegU32BE(reg byte, value uint32) error {
 // without a I2C Stop condition occurring between the two messages which
 // happens if WriteBytes() then ReadBytes() functions were called individually.
 func (o *Options) WriteThenReadBytes(writeBuf, readBuf []byte) (int, int, error) {
+	return o.device.WriteThenReadBytes(writeBuf, readBuf)
+}
 
-	msgs := []i2c_msg{
-		{
-			addr:  uint16(o.addr),
-			flags: 0,
-			len:   uint16(len(writeBuf)),
-			buf:   uintptr(unsafe.Pointer(&writeBuf[0])),
-		},
-		{
-			addr:  uint16(o.addr),
-			flags: I2C_M_RD,
-			len:   uint16(len(readBuf)),
-			buf:   uintptr(unsafe.Pointer(&readBuf[0])),
-		},
-	}
+// Close I2C-connection.
+func (o *Options) Close() error {
+	return o.bus.Close()
+}
 
-	data := i2c_rdwr_ioctl_data{
-		msgs:  uintptr(unsafe.Pointer(&msgs[0])),
-		nmsgs: uint32(len(msgs)),
-	}
+// Tx submits segments as a single I2C_RDWR ioctl; see Bus.Tx.
+func (o *Options) Tx(segments ...Segment) error {
+	return o.bus.Tx(segments...)
+}
 
-	if err := ioctl(o.rc.Fd(), I2C_RDWR, uintptr(unsafe.Pointer(&data))); err != nil {
-		return 0, 0, err
-	}
+// SMBUS SECTION
+//
+// These delegate to the underlying Device; see smbus.go for the actual
+// I2C_SMBUS ioctl handling.
+
+// Funcs returns the functionality bitmask reported by the adapter via
+// I2C_FUNCS, e.g. I2C_FUNC_I2C, I2C_FUNC_10BIT_ADDR or any of the
+// I2C_FUNC_SMBUS_* flags.
+func (o *Options) Funcs() (uint64, error) {
+	return o.bus.Funcs()
+}
 
-	return len(writeBuf), len(readBuf), nil
+// SetPEC enables or disables SMBus Packet Error Checking.
+func (o *Options) SetPEC(enable bool) error {
+	return o.device.SetPEC(enable)
 }
 
-// Close I2C-connection.
-func (o *Options) Close() error {
-	return o.rc.Close()
+// SMBusQuick sends the SMBus "quick" command.
+func (o *Options) SMBusQuick(write bool) error {
+	return o.device.SMBusQuick(write)
+}
+
+// SMBusReadByte reads a single byte without a command/register byte.
+func (o *Options) SMBusReadByte() (byte, error) {
+	return o.device.SMBusReadByte()
+}
+
+// SMBusWriteByte writes a single byte without a command/register byte.
+func (o *Options) SMBusWriteByte(value byte) error {
+	return o.device.SMBusWriteByte(value)
+}
+
+// SMBusReadByteData reads a single byte from the register specified in command.
+func (o *Options) SMBusReadByteData(command byte) (byte, error) {
+	return o.device.SMBusReadByteData(command)
+}
+
+// SMBusWriteByteData writes a single byte to the register specified in command.
+func (o *Options) SMBusWriteByteData(command byte, value byte) error {
+	return o.device.SMBusWriteByteData(command, value)
+}
+
+// SMBusReadWordData reads a 16-bit little endian word from the register specified in command.
+func (o *Options) SMBusReadWordData(command byte) (uint16, error) {
+	return o.device.SMBusReadWordData(command)
+}
+
+// SMBusWriteWordData writes a 16-bit little endian word to the register specified in command.
+func (o *Options) SMBusWriteWordData(command byte, value uint16) error {
+	return o.device.SMBusWriteWordData(command, value)
+}
+
+// SMBusProcessCall writes then reads back a 16-bit word in one transaction.
+func (o *Options) SMBusProcessCall(command byte, value uint16) (uint16, error) {
+	return o.device.SMBusProcessCall(command, value)
+}
+
+// SMBusReadBlockData reads a variable-length SMBus block from the register specified in command.
+func (o *Options) SMBusReadBlockData(command byte) ([]byte, error) {
+	return o.device.SMBusReadBlockData(command)
+}
+
+// SMBusWriteBlockData writes a variable-length SMBus block to the register specified in command.
+func (o *Options) SMBusWriteBlockData(command byte, buf []byte) error {
+	return o.device.SMBusWriteBlockData(command, buf)
+}
+
+// SMBusBlockProcessCall writes then reads back a variable-length SMBus block in one transaction.
+func (o *Options) SMBusBlockProcessCall(command byte, write []byte) ([]byte, error) {
+	return o.device.SMBusBlockProcessCall(command, write)
 }
 
 func ioctl(fd, cmd, arg uintptr) error {
@@ -0,0 +1,26 @@
+// Package i2ctest provides fakes for unit-testing drivers built on
+// github.com/swdee/go-i2c without real hardware.
+//
+// Conn is the shape both *i2c.Options and *i2c.Device already satisfy, so a
+// driver written against Conn instead of the concrete types can be
+// pointed at Playback or a register-map mock in tests and at the real
+// bus in production.
+package i2ctest
+
+import "github.com/swdee/go-i2c"
+
+// Conn is the subset of the i2c package's API a driver typically needs.
+// *i2c.Options and *i2c.Device both implement it.
+type Conn interface {
+	ReadBytes(buf []byte) (int, error)
+	WriteBytes(buf []byte) (int, error)
+	WriteThenReadBytes(writeBuf, readBuf []byte) (int, int, error)
+	Tx(segments ...i2c.Segment) error
+
+	SMBusReadByte() (byte, error)
+	SMBusWriteByte(value byte) error
+	SMBusReadByteData(command byte) (byte, error)
+	SMBusWriteByteData(command byte, value byte) error
+	SMBusReadWordData(command byte) (uint16, error)
+	SMBusWriteWordData(command byte, value uint16) error
+}
@@ -0,0 +1,200 @@
+package i2ctest
+
+import (
+	"bytes"
+
+	"github.com/swdee/go-i2c"
+)
+
+var _ Conn = (*Playback)(nil)
+
+// TestingT is the subset of *testing.T that Playback needs. It lets
+// Playback be used from table-driven tests without importing "testing"
+// into this package's API.
+type TestingT interface {
+	Helper()
+	Fatalf(format string, args ...interface{})
+}
+
+// Op is one expected transaction: a write of Write, optionally followed
+// by a read that Playback satisfies with Read. A pure write op leaves
+// Read nil; a pure read op leaves Write nil.
+type Op struct {
+	Addr  uint16
+	Write []byte
+	Read  []byte
+}
+
+// Playback is a Conn that replays an ordered list of expected Ops,
+// failing the test via TestingT as soon as a call doesn't match the next
+// expected Op.
+type Playback struct {
+	t   TestingT
+	ops []Op
+	pos int
+}
+
+// NewPlayback returns a Playback that expects ops in order.
+func NewPlayback(t TestingT, ops []Op) *Playback {
+	return &Playback{t: t, ops: ops}
+}
+
+// Done fails the test if any expected Ops were never consumed.
+func (p *Playback) Done() {
+	p.t.Helper()
+
+	if p.pos != len(p.ops) {
+		p.t.Fatalf("i2ctest: %d expected op(s) never happened, starting at %+v", len(p.ops)-p.pos, p.ops[p.pos])
+	}
+}
+
+func (p *Playback) next() *Op {
+	p.t.Helper()
+
+	if p.pos >= len(p.ops) {
+		p.t.Fatalf("i2ctest: unexpected transaction, no more ops expected")
+		return nil
+	}
+
+	op := &p.ops[p.pos]
+	p.pos++
+	return op
+}
+
+// WriteBytes matches the next Op, which must be a pure write.
+func (p *Playback) WriteBytes(buf []byte) (int, error) {
+	p.t.Helper()
+
+	op := p.next()
+
+	if op.Read != nil {
+		p.t.Fatalf("i2ctest: WriteBytes(% x) but next op %+v expects a read", buf, op)
+	}
+	if !bytes.Equal(op.Write, buf) {
+		p.t.Fatalf("i2ctest: WriteBytes(% x), want % x", buf, op.Write)
+	}
+
+	return len(buf), nil
+}
+
+// ReadBytes matches the next Op, which must be a pure read, and copies
+// its Read bytes into buf.
+func (p *Playback) ReadBytes(buf []byte) (int, error) {
+	p.t.Helper()
+
+	op := p.next()
+
+	if op.Write != nil {
+		p.t.Fatalf("i2ctest: ReadBytes(len %d) but next op %+v expects a write", len(buf), op)
+	}
+	if len(op.Read) != len(buf) {
+		p.t.Fatalf("i2ctest: ReadBytes(len %d), want len %d", len(buf), len(op.Read))
+	}
+
+	copy(buf, op.Read)
+
+	return len(buf), nil
+}
+
+// WriteThenReadBytes matches the next Op against both writeBuf and
+// readBuf at once.
+func (p *Playback) WriteThenReadBytes(writeBuf, readBuf []byte) (int, int, error) {
+	p.t.Helper()
+
+	op := p.next()
+
+	if !bytes.Equal(op.Write, writeBuf) {
+		p.t.Fatalf("i2ctest: WriteThenReadBytes write % x, want % x", writeBuf, op.Write)
+	}
+	if len(op.Read) != len(readBuf) {
+		p.t.Fatalf("i2ctest: WriteThenReadBytes read len %d, want len %d", len(readBuf), len(op.Read))
+	}
+
+	copy(readBuf, op.Read)
+
+	return len(writeBuf), len(readBuf), nil
+}
+
+// Tx matches one Op per segment, in order: Addr must match the segment's
+// Addr, and a write segment is matched against Write while a read
+// segment is matched against (and filled from) Read. Unlike the plain
+// ReadBytes/WriteBytes/WriteThenReadBytes methods, segments carry their
+// own address, since a single Tx can target more than one device, so Tx
+// is the one place Op.Addr is actually checked.
+func (p *Playback) Tx(segments ...i2c.Segment) error {
+	p.t.Helper()
+
+	for _, s := range segments {
+
+		op := p.next()
+
+		if op.Addr != s.Addr {
+			p.t.Fatalf("i2ctest: Tx segment addr %#02x, want %#02x", s.Addr, op.Addr)
+		}
+
+		if s.Read {
+			if len(op.Read) != len(s.Buf) {
+				p.t.Fatalf("i2ctest: Tx read len %d, want len %d", len(s.Buf), len(op.Read))
+			}
+			copy(s.Buf, op.Read)
+		} else {
+			if !bytes.Equal(op.Write, s.Buf) {
+				p.t.Fatalf("i2ctest: Tx write % x, want % x", s.Buf, op.Write)
+			}
+		}
+	}
+
+	return nil
+}
+
+// SMBusReadByte matches a pure 1-byte read Op.
+func (p *Playback) SMBusReadByte() (byte, error) {
+	buf := make([]byte, 1)
+
+	if _, err := p.ReadBytes(buf); err != nil {
+		return 0, err
+	}
+
+	return buf[0], nil
+}
+
+// SMBusWriteByte matches a pure 1-byte write Op.
+func (p *Playback) SMBusWriteByte(value byte) error {
+	_, err := p.WriteBytes([]byte{value})
+	return err
+}
+
+// SMBusReadByteData matches a write of [command] followed by a 1-byte read.
+func (p *Playback) SMBusReadByteData(command byte) (byte, error) {
+	buf := make([]byte, 1)
+
+	if _, _, err := p.WriteThenReadBytes([]byte{command}, buf); err != nil {
+		return 0, err
+	}
+
+	return buf[0], nil
+}
+
+// SMBusWriteByteData matches a pure write Op of [command, value].
+func (p *Playback) SMBusWriteByteData(command byte, value byte) error {
+	_, err := p.WriteBytes([]byte{command, value})
+	return err
+}
+
+// SMBusReadWordData matches a write of [command] followed by a 2-byte
+// little endian read.
+func (p *Playback) SMBusReadWordData(command byte) (uint16, error) {
+	buf := make([]byte, 2)
+
+	if _, _, err := p.WriteThenReadBytes([]byte{command}, buf); err != nil {
+		return 0, err
+	}
+
+	return uint16(buf[0]) | uint16(buf[1])<<8, nil
+}
+
+// SMBusWriteWordData matches a pure write Op of [command, lo, hi].
+func (p *Playback) SMBusWriteWordData(command byte, value uint16) error {
+	_, err := p.WriteBytes([]byte{command, byte(value), byte(value >> 8)})
+	return err
+}
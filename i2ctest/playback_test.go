@@ -0,0 +1,116 @@
+package i2ctest
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/swdee/go-i2c"
+)
+
+// fakeT is a minimal TestingT that panics on Fatalf instead of aborting
+// the goroutine the way *testing.T does, so failure-path tests can
+// recover the panic and assert on it without taking down the whole test
+// binary.
+type fakeT struct{}
+
+func (fakeT) Helper() {}
+
+func (fakeT) Fatalf(format string, args ...interface{}) {
+	panic(fmt.Sprintf(format, args...))
+}
+
+func expectFatal(t *testing.T, fn func()) {
+	t.Helper()
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("expected a Fatalf, got none")
+		}
+	}()
+
+	fn()
+}
+
+func TestPlaybackSuccess(t *testing.T) {
+
+	p := NewPlayback(fakeT{}, []Op{
+		{Addr: 0x50, Write: []byte{0x01}},
+		{Addr: 0x50, Read: []byte{0xAA}},
+		{Addr: 0x50, Write: []byte{0x02}, Read: []byte{0xBB, 0xCC}},
+	})
+
+	if _, err := p.WriteBytes([]byte{0x01}); err != nil {
+		t.Fatalf("WriteBytes: %v", err)
+	}
+
+	buf := make([]byte, 1)
+	if _, err := p.ReadBytes(buf); err != nil {
+		t.Fatalf("ReadBytes: %v", err)
+	}
+	if buf[0] != 0xAA {
+		t.Errorf("ReadBytes got %#x, want 0xAA", buf[0])
+	}
+
+	readBuf := make([]byte, 2)
+	if _, _, err := p.WriteThenReadBytes([]byte{0x02}, readBuf); err != nil {
+		t.Fatalf("WriteThenReadBytes: %v", err)
+	}
+	if readBuf[0] != 0xBB || readBuf[1] != 0xCC {
+		t.Errorf("WriteThenReadBytes got % x, want bb cc", readBuf)
+	}
+
+	p.Done()
+}
+
+func TestPlaybackTxChecksAddr(t *testing.T) {
+
+	p := NewPlayback(fakeT{}, []Op{
+		{Addr: 0x50, Write: []byte{0x01}},
+	})
+
+	expectFatal(t, func() {
+		p.Tx(i2c.WriteSegment(0x51, []byte{0x01}))
+	})
+}
+
+func TestPlaybackWriteBytesMismatch(t *testing.T) {
+
+	p := NewPlayback(fakeT{}, []Op{
+		{Addr: 0x50, Write: []byte{0x01}},
+	})
+
+	expectFatal(t, func() {
+		p.WriteBytes([]byte{0x02})
+	})
+}
+
+func TestPlaybackReadBytesLengthMismatch(t *testing.T) {
+
+	p := NewPlayback(fakeT{}, []Op{
+		{Addr: 0x50, Read: []byte{0xAA, 0xBB}},
+	})
+
+	expectFatal(t, func() {
+		p.ReadBytes(make([]byte, 1))
+	})
+}
+
+func TestPlaybackDoneFailsOnLeftoverOps(t *testing.T) {
+
+	p := NewPlayback(fakeT{}, []Op{
+		{Addr: 0x50, Write: []byte{0x01}},
+	})
+
+	expectFatal(t, func() {
+		p.Done()
+	})
+}
+
+func TestPlaybackUnexpectedTransaction(t *testing.T) {
+
+	p := NewPlayback(fakeT{}, nil)
+
+	expectFatal(t, func() {
+		p.WriteBytes([]byte{0x01})
+	})
+}
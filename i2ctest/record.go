@@ -0,0 +1,169 @@
+package i2ctest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/swdee/go-i2c"
+)
+
+var _ Conn = (*Record)(nil)
+
+// Record wraps a real Conn (typically an *i2c.Options or *i2c.Device
+// talking to actual hardware) and captures every transaction it sees.
+// Dump renders the capture as Go source suitable for pasting into a
+// Playback-based test, so a driver test can be bootstrapped from one run
+// against real hardware.
+type Record struct {
+	conn Conn
+	ops  []Op
+}
+
+// NewRecord wraps conn for recording.
+func NewRecord(conn Conn) *Record {
+	return &Record{conn: conn}
+}
+
+// Ops returns the transactions captured so far.
+func (r *Record) Ops() []Op {
+	return r.ops
+}
+
+// Dump renders the captured transactions as a Go []i2ctest.Op literal.
+func (r *Record) Dump() string {
+
+	var b strings.Builder
+
+	fmt.Fprintln(&b, "[]i2ctest.Op{")
+
+	for _, op := range r.ops {
+		fmt.Fprintf(&b, "\t{Addr: %#02x, Write: %#v, Read: %#v},\n", op.Addr, op.Write, op.Read)
+	}
+
+	fmt.Fprintln(&b, "}")
+
+	return b.String()
+}
+
+func (r *Record) WriteBytes(buf []byte) (int, error) {
+
+	n, err := r.conn.WriteBytes(buf)
+
+	if err == nil {
+		r.ops = append(r.ops, Op{Write: append([]byte(nil), buf...)})
+	}
+
+	return n, err
+}
+
+func (r *Record) ReadBytes(buf []byte) (int, error) {
+
+	n, err := r.conn.ReadBytes(buf)
+
+	if err == nil {
+		r.ops = append(r.ops, Op{Read: append([]byte(nil), buf...)})
+	}
+
+	return n, err
+}
+
+func (r *Record) WriteThenReadBytes(writeBuf, readBuf []byte) (int, int, error) {
+
+	wn, rn, err := r.conn.WriteThenReadBytes(writeBuf, readBuf)
+
+	if err == nil {
+		r.ops = append(r.ops, Op{
+			Write: append([]byte(nil), writeBuf...),
+			Read:  append([]byte(nil), readBuf...),
+		})
+	}
+
+	return wn, rn, err
+}
+
+func (r *Record) Tx(segments ...i2c.Segment) error {
+
+	err := r.conn.Tx(segments...)
+
+	if err == nil {
+		for _, s := range segments {
+			op := Op{Addr: s.Addr}
+
+			if s.Read {
+				op.Read = append([]byte(nil), s.Buf...)
+			} else {
+				op.Write = append([]byte(nil), s.Buf...)
+			}
+
+			r.ops = append(r.ops, op)
+		}
+	}
+
+	return err
+}
+
+func (r *Record) SMBusReadByte() (byte, error) {
+
+	v, err := r.conn.SMBusReadByte()
+
+	if err == nil {
+		r.ops = append(r.ops, Op{Read: []byte{v}})
+	}
+
+	return v, err
+}
+
+func (r *Record) SMBusWriteByte(value byte) error {
+
+	err := r.conn.SMBusWriteByte(value)
+
+	if err == nil {
+		r.ops = append(r.ops, Op{Write: []byte{value}})
+	}
+
+	return err
+}
+
+func (r *Record) SMBusReadByteData(command byte) (byte, error) {
+
+	v, err := r.conn.SMBusReadByteData(command)
+
+	if err == nil {
+		r.ops = append(r.ops, Op{Write: []byte{command}, Read: []byte{v}})
+	}
+
+	return v, err
+}
+
+func (r *Record) SMBusWriteByteData(command byte, value byte) error {
+
+	err := r.conn.SMBusWriteByteData(command, value)
+
+	if err == nil {
+		r.ops = append(r.ops, Op{Write: []byte{command, value}})
+	}
+
+	return err
+}
+
+func (r *Record) SMBusReadWordData(command byte) (uint16, error) {
+
+	v, err := r.conn.SMBusReadWordData(command)
+
+	if err == nil {
+		r.ops = append(r.ops, Op{Write: []byte{command}, Read: []byte{byte(v), byte(v >> 8)}})
+	}
+
+	return v, err
+}
+
+func (r *Record) SMBusWriteWordData(command byte, value uint16) error {
+
+	err := r.conn.SMBusWriteWordData(command, value)
+
+	if err == nil {
+		r.ops = append(r.ops, Op{Write: []byte{command, byte(value), byte(value >> 8)}})
+	}
+
+	return err
+}
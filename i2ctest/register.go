@@ -0,0 +1,218 @@
+package i2ctest
+
+import (
+	"fmt"
+
+	"github.com/swdee/go-i2c"
+)
+
+var _ Conn = (*RegisterDevice8)(nil)
+
+// RegisterDevice8 fakes a chip with an 8-bit register file at a single
+// address, in the style of tinygo's tester.I2CDevice8: declare the
+// registers up front, then let the driver under test read and write them
+// through the normal Conn methods while assertions look at Regs
+// directly, instead of having to choreograph a Playback for every
+// register access.
+type RegisterDevice8 struct {
+	Addr uint16
+	Regs map[byte]byte
+
+	ptr byte // register set by buf[0] of the last write, read from by ReadBytes
+}
+
+// NewRegisterDevice8 returns a RegisterDevice8 seeded with regs. regs may
+// be nil to start with an empty register file.
+func NewRegisterDevice8(addr uint16, regs map[byte]byte) *RegisterDevice8 {
+
+	if regs == nil {
+		regs = make(map[byte]byte)
+	}
+
+	return &RegisterDevice8{Addr: addr, Regs: regs}
+}
+
+// WriteBytes sets the register pointer from buf[0], then writes any
+// remaining bytes starting at that register, auto-incrementing.
+func (m *RegisterDevice8) WriteBytes(buf []byte) (int, error) {
+
+	if len(buf) == 0 {
+		return 0, fmt.Errorf("i2ctest: WriteBytes with empty buffer")
+	}
+
+	m.ptr = buf[0]
+
+	for i, b := range buf[1:] {
+		m.Regs[m.ptr+byte(i)] = b
+	}
+
+	return len(buf), nil
+}
+
+// ReadBytes reads len(buf) bytes starting at the current register
+// pointer, auto-incrementing, without moving the pointer.
+func (m *RegisterDevice8) ReadBytes(buf []byte) (int, error) {
+
+	for i := range buf {
+		buf[i] = m.Regs[m.ptr+byte(i)]
+	}
+
+	return len(buf), nil
+}
+
+// WriteThenReadBytes sets the register pointer via writeBuf, then reads
+// readBuf starting from there, as a single register-pointer-then-read
+// access.
+func (m *RegisterDevice8) WriteThenReadBytes(writeBuf, readBuf []byte) (int, int, error) {
+
+	if _, err := m.WriteBytes(writeBuf); err != nil {
+		return 0, 0, err
+	}
+
+	n, err := m.ReadBytes(readBuf)
+
+	return len(writeBuf), n, err
+}
+
+// Tx applies each segment in order against the register file.
+func (m *RegisterDevice8) Tx(segments ...i2c.Segment) error {
+
+	for _, s := range segments {
+
+		var err error
+
+		if s.Read {
+			_, err = m.ReadBytes(s.Buf)
+		} else {
+			_, err = m.WriteBytes(s.Buf)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SMBusReadByte reads the register at the current pointer.
+func (m *RegisterDevice8) SMBusReadByte() (byte, error) {
+	return m.Regs[m.ptr], nil
+}
+
+// SMBusWriteByte writes the register at the current pointer.
+func (m *RegisterDevice8) SMBusWriteByte(value byte) error {
+	m.Regs[m.ptr] = value
+	return nil
+}
+
+// SMBusReadByteData reads register command.
+func (m *RegisterDevice8) SMBusReadByteData(command byte) (byte, error) {
+	return m.Regs[command], nil
+}
+
+// SMBusWriteByteData writes register command.
+func (m *RegisterDevice8) SMBusWriteByteData(command byte, value byte) error {
+	m.Regs[command] = value
+	return nil
+}
+
+// SMBusReadWordData reads registers command and command+1 as a little
+// endian word.
+func (m *RegisterDevice8) SMBusReadWordData(command byte) (uint16, error) {
+	return uint16(m.Regs[command]) | uint16(m.Regs[command+1])<<8, nil
+}
+
+// SMBusWriteWordData writes registers command and command+1 from a
+// little endian word.
+func (m *RegisterDevice8) SMBusWriteWordData(command byte, value uint16) error {
+	m.Regs[command] = byte(value)
+	m.Regs[command+1] = byte(value >> 8)
+	return nil
+}
+
+// RegisterDevice16 is RegisterDevice8's counterpart for chips with a
+// 16-bit register address space, e.g. large sensor fusion ICs. Unlike
+// RegisterDevice8 it has no SMBus methods, since plain SMBus has no
+// 16-bit command byte; drivers for these chips talk to it via
+// ReadBytes/WriteBytes/WriteThenReadBytes/Tx.
+type RegisterDevice16 struct {
+	Addr uint16
+	Regs map[uint16]byte
+
+	ptr uint16
+}
+
+// NewRegisterDevice16 returns a RegisterDevice16 seeded with regs. regs
+// may be nil to start with an empty register file.
+func NewRegisterDevice16(addr uint16, regs map[uint16]byte) *RegisterDevice16 {
+
+	if regs == nil {
+		regs = make(map[uint16]byte)
+	}
+
+	return &RegisterDevice16{Addr: addr, Regs: regs}
+}
+
+// WriteBytes sets the register pointer from the big endian uint16 in
+// buf[0:2], then writes any remaining bytes starting at that register,
+// auto-incrementing.
+func (m *RegisterDevice16) WriteBytes(buf []byte) (int, error) {
+
+	if len(buf) < 2 {
+		return 0, fmt.Errorf("i2ctest: WriteBytes needs at least a 2-byte register address")
+	}
+
+	m.ptr = uint16(buf[0])<<8 | uint16(buf[1])
+
+	for i, b := range buf[2:] {
+		m.Regs[m.ptr+uint16(i)] = b
+	}
+
+	return len(buf), nil
+}
+
+// ReadBytes reads len(buf) bytes starting at the current register
+// pointer, auto-incrementing.
+func (m *RegisterDevice16) ReadBytes(buf []byte) (int, error) {
+
+	for i := range buf {
+		buf[i] = m.Regs[m.ptr+uint16(i)]
+	}
+
+	return len(buf), nil
+}
+
+// WriteThenReadBytes sets the register pointer via writeBuf, then reads
+// readBuf starting from there.
+func (m *RegisterDevice16) WriteThenReadBytes(writeBuf, readBuf []byte) (int, int, error) {
+
+	if _, err := m.WriteBytes(writeBuf); err != nil {
+		return 0, 0, err
+	}
+
+	n, err := m.ReadBytes(readBuf)
+
+	return len(writeBuf), n, err
+}
+
+// Tx applies each segment in order against the register file.
+func (m *RegisterDevice16) Tx(segments ...i2c.Segment) error {
+
+	for _, s := range segments {
+
+		var err error
+
+		if s.Read {
+			_, err = m.ReadBytes(s.Buf)
+		} else {
+			_, err = m.WriteBytes(s.Buf)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package i2ctest
+
+import "testing"
+
+func TestRegisterDevice8AutoIncrement(t *testing.T) {
+
+	d := NewRegisterDevice8(0x50, map[byte]byte{0x00: 0x11, 0x01: 0x22, 0x02: 0x33})
+
+	if _, err := d.WriteBytes([]byte{0x01, 0xAA, 0xBB}); err != nil {
+		t.Fatalf("WriteBytes: %v", err)
+	}
+
+	if d.Regs[0x01] != 0xAA || d.Regs[0x02] != 0xBB {
+		t.Errorf("WriteBytes didn't auto-increment: regs = %+v", d.Regs)
+	}
+
+	buf := make([]byte, 3)
+
+	if _, err := d.ReadBytes(buf); err != nil {
+		t.Fatalf("ReadBytes: %v", err)
+	}
+
+	want := []byte{0xAA, 0xBB, 0x00}
+
+	for i := range want {
+		if buf[i] != want[i] {
+			t.Errorf("ReadBytes[%d] = %#x, want %#x", i, buf[i], want[i])
+		}
+	}
+}
+
+func TestRegisterDevice8WriteThenReadBytes(t *testing.T) {
+
+	d := NewRegisterDevice8(0x50, map[byte]byte{0x05: 0x42})
+
+	buf := make([]byte, 1)
+
+	if _, _, err := d.WriteThenReadBytes([]byte{0x05}, buf); err != nil {
+		t.Fatalf("WriteThenReadBytes: %v", err)
+	}
+
+	if buf[0] != 0x42 {
+		t.Errorf("got %#x, want 0x42", buf[0])
+	}
+}
+
+func TestRegisterDevice16AutoIncrement(t *testing.T) {
+
+	d := NewRegisterDevice16(0x50, map[uint16]byte{0x0100: 0x11, 0x0101: 0x22, 0x0102: 0x33})
+
+	if _, err := d.WriteBytes([]byte{0x01, 0x01, 0xAA, 0xBB}); err != nil {
+		t.Fatalf("WriteBytes: %v", err)
+	}
+
+	if d.Regs[0x0101] != 0xAA || d.Regs[0x0102] != 0xBB {
+		t.Errorf("WriteBytes didn't auto-increment: regs = %+v", d.Regs)
+	}
+
+	buf := make([]byte, 3)
+
+	if _, err := d.ReadBytes(buf); err != nil {
+		t.Fatalf("ReadBytes: %v", err)
+	}
+
+	want := []byte{0xAA, 0xBB, 0x00}
+
+	for i := range want {
+		if buf[i] != want[i] {
+			t.Errorf("ReadBytes[%d] = %#x, want %#x", i, buf[i], want[i])
+		}
+	}
+}
+
+func TestRegisterDevice16ShortWriteErrors(t *testing.T) {
+
+	d := NewRegisterDevice16(0x50, nil)
+
+	if _, err := d.WriteBytes([]byte{0x01}); err == nil {
+		t.Fatalf("expected an error writing a single byte, got none")
+	}
+}
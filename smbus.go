@@ -0,0 +1,385 @@
+package i2c
+
+import (
+	"errors"
+	"fmt"
+	"unsafe"
+)
+
+// i2c_smbus_ioctl_data mirrors struct i2c_smbus_ioctl_data from
+// linux/i2c-dev.h. data points at an i2c_smbus_block, which is large
+// enough to hold the union i2c_smbus_data used by the kernel for every
+// transaction size, byte/word replies included.
+type i2c_smbus_ioctl_data struct {
+	readWrite uint8
+	command   uint8
+	size      uint32
+	data      uintptr
+}
+
+// i2c_smbus_block is the Go side of union i2c_smbus_data. The kernel
+// reserves I2C_SMBUS_BLOCK_MAX+2 bytes so a full block transfer plus its
+// length prefix always fits, even though byte/word replies only use the
+// first one or two bytes.
+type i2c_smbus_block [I2C_SMBUS_BLOCK_MAX + 2]byte
+
+// smbusAccess issues one SMBus transaction against d's address, natively
+// via I2C_SMBUS where the adapter supports it, or emulated over
+// I2C_RDWR otherwise. Some adapters, the common i2c-gpio bitbang driver
+// among them, never implement I2C_SMBUS at all, so drivers written
+// against the SMBusXxx methods would otherwise be unusable on them even
+// though the equivalent plain I2C messages work fine.
+func (d *Device) smbusAccess(readWrite uint8, command uint8, size uint32, data *i2c_smbus_block) error {
+
+	funcs, err := d.bus.Funcs()
+
+	if err != nil {
+		return err
+	}
+
+	if want := smbusFunc(readWrite, size); want&funcs == want {
+		return d.smbusIoctl(readWrite, command, size, data)
+	}
+
+	return d.smbusEmulate(readWrite, command, size, data)
+}
+
+// smbusFunc returns the I2C_FUNC_SMBUS_* bit the adapter must report in
+// I2C_FUNCS for smbusAccess to service (readWrite, size) via the native
+// I2C_SMBUS ioctl.
+func smbusFunc(readWrite uint8, size uint32) uint64 {
+
+	read := readWrite == I2C_SMBUS_READ
+
+	switch size {
+	case I2C_SMBUS_QUICK:
+		return I2C_FUNC_SMBUS_QUICK
+	case I2C_SMBUS_BYTE:
+		if read {
+			return I2C_FUNC_SMBUS_READ_BYTE
+		}
+		return I2C_FUNC_SMBUS_WRITE_BYTE
+	case I2C_SMBUS_BYTE_DATA:
+		if read {
+			return I2C_FUNC_SMBUS_READ_BYTE_DATA
+		}
+		return I2C_FUNC_SMBUS_WRITE_BYTE_DATA
+	case I2C_SMBUS_WORD_DATA:
+		if read {
+			return I2C_FUNC_SMBUS_READ_WORD_DATA
+		}
+		return I2C_FUNC_SMBUS_WRITE_WORD_DATA
+	case I2C_SMBUS_PROC_CALL:
+		return I2C_FUNC_SMBUS_PROC_CALL
+	case I2C_SMBUS_BLOCK_DATA:
+		if read {
+			return I2C_FUNC_SMBUS_READ_BLOCK_DATA
+		}
+		return I2C_FUNC_SMBUS_WRITE_BLOCK_DATA
+	case I2C_SMBUS_BLOCK_PROC_CALL:
+		return I2C_FUNC_SMBUS_BLOCK_PROC_CALL
+	}
+
+	return 0
+}
+
+// smbusIoctl issues a single I2C_SMBUS ioctl against d's address. The
+// I2C_SMBUS ioctl has no address parameter of its own, it always targets
+// whatever address I2C_SLAVE/I2C_SLAVE_FORCE last attached on this fd,
+// so the Bus mutex is held across the attach and the ioctl to keep it
+// race-free against other Devices sharing the same Bus. attachCmd picks
+// I2C_SLAVE_FORCE over I2C_SLAVE when d was marked via SetForce, and
+// I2C_TENBIT is toggled to match d.tenBit first, since I2C_SLAVE/
+// I2C_SMBUS only treat the address as 10-bit once that's set on the fd.
+func (d *Device) smbusIoctl(readWrite uint8, command uint8, size uint32, data *i2c_smbus_block) error {
+
+	d.bus.mu.Lock()
+	defer d.bus.mu.Unlock()
+
+	var tenBit uintptr
+
+	if d.tenBit {
+		tenBit = 1
+	}
+
+	if err := ioctl(d.bus.rc.Fd(), I2C_TENBIT, tenBit); err != nil {
+		return err
+	}
+
+	attachCmd := uintptr(I2C_SLAVE)
+
+	if d.forceAttach {
+		attachCmd = I2C_SLAVE_FORCE
+	}
+
+	if err := ioctl(d.bus.rc.Fd(), attachCmd, uintptr(d.addr)); err != nil {
+		return err
+	}
+
+	args := i2c_smbus_ioctl_data{
+		readWrite: readWrite,
+		command:   command,
+		size:      size,
+		data:      uintptr(unsafe.Pointer(data)),
+	}
+
+	return ioctl(d.bus.rc.Fd(), I2C_SMBUS, uintptr(unsafe.Pointer(&args)))
+}
+
+// smbusEmulate reproduces an SMBus operation the adapter can't service
+// natively (smbusAccess found I2C_FUNCS missing the matching
+// I2C_FUNC_SMBUS_* bit) as plain I2C_RDWR messages instead, the same
+// transactions i2c-tools falls back to with `i2cget -y`/`i2cset -y`.
+// Quick is a zero-length message rather than a ReadBytes/WriteBytes
+// call, since those reject empty buffers. The block variants have no
+// sensible emulation without native adapter support, since the reply
+// length itself is something only the native ioctl or I2C_M_RECV_LEN
+// (which requires the same func bit) can discover.
+func (d *Device) smbusEmulate(readWrite uint8, command uint8, size uint32, data *i2c_smbus_block) error {
+
+	read := readWrite == I2C_SMBUS_READ
+
+	switch size {
+	case I2C_SMBUS_QUICK:
+		flags := d.flags()
+
+		if read {
+			flags |= I2C_M_RD
+		}
+
+		return d.bus.tx([]i2c_msg{{addr: d.addr, flags: flags}})
+
+	case I2C_SMBUS_BYTE:
+		if read {
+			buf := make([]byte, 1)
+
+			if _, err := d.ReadBytes(buf); err != nil {
+				return err
+			}
+
+			data[0] = buf[0]
+			return nil
+		}
+
+		_, err := d.WriteBytes([]byte{command})
+		return err
+
+	case I2C_SMBUS_BYTE_DATA:
+		if read {
+			buf := make([]byte, 1)
+
+			if _, _, err := d.WriteThenReadBytes([]byte{command}, buf); err != nil {
+				return err
+			}
+
+			data[0] = buf[0]
+			return nil
+		}
+
+		_, err := d.WriteBytes([]byte{command, data[0]})
+		return err
+
+	case I2C_SMBUS_WORD_DATA:
+		if read {
+			buf := make([]byte, 2)
+
+			if _, _, err := d.WriteThenReadBytes([]byte{command}, buf); err != nil {
+				return err
+			}
+
+			data[0], data[1] = buf[0], buf[1]
+			return nil
+		}
+
+		_, err := d.WriteBytes([]byte{command, data[0], data[1]})
+		return err
+
+	case I2C_SMBUS_PROC_CALL:
+		buf := make([]byte, 2)
+
+		if _, _, err := d.WriteThenReadBytes([]byte{command, data[0], data[1]}, buf); err != nil {
+			return err
+		}
+
+		data[0], data[1] = buf[0], buf[1]
+		return nil
+	}
+
+	return fmt.Errorf("i2c: adapter lacks native SMBus support for this operation (size %#x) and it has no I2C_RDWR emulation", size)
+}
+
+// SetPEC enables or disables SMBus Packet Error Checking on the Bus's
+// file descriptor, so it applies to every Device sharing that Bus, not
+// only this one. Not every adapter implements PEC; check Funcs for I2C_FUNC_SMBUS_PEC
+// before relying on it.
+func (d *Device) SetPEC(enable bool) error {
+
+	var arg uintptr
+
+	if enable {
+		arg = 1
+	}
+
+	return ioctl(d.bus.rc.Fd(), I2C_PEC, arg)
+}
+
+// SMBusQuick sends the SMBus "quick" command: the read/write bit itself
+// carries the only payload. It is mainly used to probe whether a device
+// is present on the bus, see Scan.
+func (d *Device) SMBusQuick(write bool) error {
+
+	rw := uint8(I2C_SMBUS_READ)
+
+	if write {
+		rw = I2C_SMBUS_WRITE
+	}
+
+	return d.smbusAccess(rw, 0, I2C_SMBUS_QUICK, nil)
+}
+
+// SMBusReadByte reads a single byte from the device without specifying a
+// command/register byte first.
+func (d *Device) SMBusReadByte() (byte, error) {
+
+	var data i2c_smbus_block
+
+	if err := d.smbusAccess(I2C_SMBUS_READ, 0, I2C_SMBUS_BYTE, &data); err != nil {
+		return 0, err
+	}
+
+	return data[0], nil
+}
+
+// SMBusWriteByte writes a single byte to the device without a
+// command/register byte.
+func (d *Device) SMBusWriteByte(value byte) error {
+	return d.smbusAccess(I2C_SMBUS_WRITE, value, I2C_SMBUS_BYTE, nil)
+}
+
+// SMBusReadByteData reads a single byte from the device register
+// specified in command.
+func (d *Device) SMBusReadByteData(command byte) (byte, error) {
+
+	var data i2c_smbus_block
+
+	if err := d.smbusAccess(I2C_SMBUS_READ, command, I2C_SMBUS_BYTE_DATA, &data); err != nil {
+		return 0, err
+	}
+
+	return data[0], nil
+}
+
+// SMBusWriteByteData writes a single byte to the device register
+// specified in command.
+func (d *Device) SMBusWriteByteData(command byte, value byte) error {
+
+	var data i2c_smbus_block
+	data[0] = value
+
+	return d.smbusAccess(I2C_SMBUS_WRITE, command, I2C_SMBUS_BYTE_DATA, &data)
+}
+
+// SMBusReadWordData reads a 16-bit little endian word from the device
+// register specified in command.
+func (d *Device) SMBusReadWordData(command byte) (uint16, error) {
+
+	var data i2c_smbus_block
+
+	if err := d.smbusAccess(I2C_SMBUS_READ, command, I2C_SMBUS_WORD_DATA, &data); err != nil {
+		return 0, err
+	}
+
+	return uint16(data[0]) | uint16(data[1])<<8, nil
+}
+
+// SMBusWriteWordData writes a 16-bit little endian word to the device
+// register specified in command.
+func (d *Device) SMBusWriteWordData(command byte, value uint16) error {
+
+	var data i2c_smbus_block
+	data[0] = byte(value)
+	data[1] = byte(value >> 8)
+
+	return d.smbusAccess(I2C_SMBUS_WRITE, command, I2C_SMBUS_WORD_DATA, &data)
+}
+
+// SMBusProcessCall writes a 16-bit word to the device register specified
+// in command, then reads back a 16-bit word in the same transaction.
+func (d *Device) SMBusProcessCall(command byte, value uint16) (uint16, error) {
+
+	var data i2c_smbus_block
+	data[0] = byte(value)
+	data[1] = byte(value >> 8)
+
+	if err := d.smbusAccess(I2C_SMBUS_WRITE, command, I2C_SMBUS_PROC_CALL, &data); err != nil {
+		return 0, err
+	}
+
+	return uint16(data[0]) | uint16(data[1])<<8, nil
+}
+
+// SMBusReadBlockData reads a variable-length SMBus block from the device
+// register specified in command. The leading length byte the device
+// returns is consumed internally; the returned slice holds only the
+// block payload, capped at I2C_SMBUS_BLOCK_MAX bytes.
+func (d *Device) SMBusReadBlockData(command byte) ([]byte, error) {
+
+	var data i2c_smbus_block
+
+	if err := d.smbusAccess(I2C_SMBUS_READ, command, I2C_SMBUS_BLOCK_DATA, &data); err != nil {
+		return nil, err
+	}
+
+	n := data[0]
+
+	if n > I2C_SMBUS_BLOCK_MAX {
+		n = I2C_SMBUS_BLOCK_MAX
+	}
+
+	buf := make([]byte, n)
+	copy(buf, data[1:1+n])
+
+	return buf, nil
+}
+
+// SMBusWriteBlockData writes a variable-length SMBus block to the device
+// register specified in command. buf is capped at I2C_SMBUS_BLOCK_MAX
+// bytes; the leading length byte is prepended internally.
+func (d *Device) SMBusWriteBlockData(command byte, buf []byte) error {
+
+	if len(buf) > I2C_SMBUS_BLOCK_MAX {
+		return errors.New("i2c: SMBus block data exceeds I2C_SMBUS_BLOCK_MAX")
+	}
+
+	var data i2c_smbus_block
+	data[0] = byte(len(buf))
+	copy(data[1:], buf)
+
+	return d.smbusAccess(I2C_SMBUS_WRITE, command, I2C_SMBUS_BLOCK_DATA, &data)
+}
+
+// SMBusBlockProcessCall writes a variable-length SMBus block to the
+// device register specified in command, then reads back a
+// variable-length block in the same transaction. write is capped at
+// I2C_SMBUS_BLOCK_MAX bytes.
+func (d *Device) SMBusBlockProcessCall(command byte, write []byte) ([]byte, error) {
+
+	if len(write) > I2C_SMBUS_BLOCK_MAX {
+		return nil, errors.New("i2c: SMBus block data exceeds I2C_SMBUS_BLOCK_MAX")
+	}
+
+	var data i2c_smbus_block
+	data[0] = byte(len(write))
+	copy(data[1:], write)
+
+	if err := d.smbusAccess(I2C_SMBUS_WRITE, command, I2C_SMBUS_BLOCK_PROC_CALL, &data); err != nil {
+		return nil, err
+	}
+
+	n := data[0]
+
+	buf := make([]byte, n)
+	copy(buf, data[1:1+n])
+
+	return buf, nil
+}
@@ -0,0 +1,30 @@
+package i2c
+
+import "errors"
+
+// SpeedHook reconfigures a platform's I2C controller clock to hz Hertz.
+// Linux has no generic per-adapter ioctl for bus speed; it is set via
+// board-specific registers, so platform support packages (e.g. for the
+// Raspberry Pi) register their own implementation with RegisterSpeedHook.
+type SpeedHook func(hz int) error
+
+var speedHook SpeedHook
+
+// RegisterSpeedHook installs the platform-specific implementation used
+// by SetSpeed. Call it once from platform init code; the last hook
+// registered wins.
+func RegisterSpeedHook(hook SpeedHook) {
+	speedHook = hook
+}
+
+// SetSpeed reconfigures the bus clock to hz Hertz via the hook installed
+// with RegisterSpeedHook. It returns an error if no hook has been
+// registered rather than silently doing nothing.
+func SetSpeed(hz int) error {
+
+	if speedHook == nil {
+		return errors.New("i2c: SetSpeed called with no hook registered, see RegisterSpeedHook")
+	}
+
+	return speedHook(hz)
+}
@@ -0,0 +1,143 @@
+package i2c
+
+import (
+	"fmt"
+	"unsafe"
+)
+
+// Segment is one message (what the kernel calls an i2c_msg) within a
+// multi-segment Tx. A write-write-read sequence for a 16-bit-addressed
+// EEPROM read, for example, is three Segments submitted together so the
+// adapter never issues a STOP between them.
+type Segment struct {
+	Addr uint16 // slave address this segment targets
+	Read bool   // false writes Buf, true fills Buf by reading
+	Buf  []byte
+
+	NoStart   bool // I2C_M_NOSTART: don't send a START/address before this segment
+	IgnoreNak bool // I2C_M_IGNORE_NAK: treat a NAK on this segment as success
+	TenBit    bool // I2C_M_TEN: Addr is a 10-bit address
+	RecvLen   bool // I2C_M_RECV_LEN: slave supplies the length as its first reply byte
+}
+
+// WriteSegment builds a write Segment addressed to addr.
+func WriteSegment(addr uint16, buf []byte) Segment {
+	return Segment{Addr: addr, Buf: buf}
+}
+
+// ReadSegment builds a read Segment addressed to addr. buf's length
+// determines how many bytes are read, except when RecvLen is set
+// afterwards, in which case buf must have room for the length byte plus
+// the largest reply the slave may send.
+func ReadSegment(addr uint16, buf []byte) Segment {
+	return Segment{Addr: addr, Buf: buf, Read: true}
+}
+
+func (s Segment) flags() uint16 {
+
+	var f uint16
+
+	if s.Read {
+		f |= I2C_M_RD
+	}
+	if s.NoStart {
+		f |= I2C_M_NOSTART
+	}
+	if s.IgnoreNak {
+		f |= I2C_M_IGNORE_NAK
+	}
+	if s.TenBit {
+		f |= I2C_M_TEN
+	}
+	if s.RecvLen {
+		f |= I2C_M_RECV_LEN
+	}
+
+	return f
+}
+
+// requiredFuncs returns the I2C_FUNC_* bit(s) the adapter must report in
+// order to honor this Segment's flags.
+func (s Segment) requiredFuncs() uint64 {
+
+	var want uint64
+
+	if s.NoStart {
+		want |= I2C_FUNC_NOSTART
+	}
+	if s.IgnoreNak {
+		want |= I2C_FUNC_PROTOCOL_MANGLING
+	}
+	if s.TenBit {
+		want |= I2C_FUNC_10BIT_ADDR
+	}
+	if s.RecvLen {
+		want |= I2C_FUNC_SMBUS_READ_BLOCK_DATA
+	}
+
+	return want
+}
+
+// Tx submits segments as a single I2C_RDWR ioctl, so the adapter issues
+// one repeated-start sequence across all of them instead of a separate
+// START/STOP per segment. Each segment's flags are checked against the
+// adapter's I2C_FUNCS functionality bitmask first, so an unsupported flag
+// fails with a clear error instead of being silently ignored by the
+// kernel or adapter.
+func (b *Bus) Tx(segments ...Segment) error {
+
+	if len(segments) == 0 {
+		return nil
+	}
+
+	funcs, err := b.Funcs()
+
+	if err != nil {
+		return err
+	}
+
+	msgs := make([]i2c_msg, len(segments))
+
+	for i, s := range segments {
+
+		if want := s.requiredFuncs(); want&funcs != want {
+			return fmt.Errorf("i2c: segment %d needs adapter functionality %#x, adapter reports %#x", i, want, funcs)
+		}
+
+		if len(s.Buf) == 0 {
+			return fmt.Errorf("i2c: segment %d has an empty buffer", i)
+		}
+
+		msgs[i] = i2c_msg{
+			addr:  s.Addr,
+			flags: s.flags(),
+			len:   uint16(len(s.Buf)),
+			buf:   uintptr(unsafe.Pointer(&s.Buf[0])),
+		}
+	}
+
+	return b.tx(msgs)
+}
+
+// Tx submits segments as a single I2C_RDWR ioctl; see Bus.Tx. It is
+// provided on Device too, alongside ReadBytes/WriteBytes/WriteThenReadBytes
+// and the SMBus methods, so driver code written against Device (the
+// concurrent-safe API from NewBus/Bus.Device) satisfies i2ctest.Conn the
+// same way Options does.
+func (d *Device) Tx(segments ...Segment) error {
+	return d.bus.Tx(segments...)
+}
+
+// Funcs returns the functionality bitmask reported by the adapter via
+// I2C_FUNCS, e.g. I2C_FUNC_I2C, I2C_FUNC_10BIT_ADDR or any of the
+// I2C_FUNC_SMBUS_* flags.
+func (b *Bus) Funcs() (uint64, error) {
+
+	var funcs uint64
+
+	if err := ioctl(b.rc.Fd(), I2C_FUNCS, uintptr(unsafe.Pointer(&funcs))); err != nil {
+		return 0, err
+	}
+
+	return funcs, nil
+}
@@ -0,0 +1,61 @@
+package i2c
+
+import "testing"
+
+func TestSegmentFlags(t *testing.T) {
+
+	cases := []struct {
+		name string
+		seg  Segment
+		want uint16
+	}{
+		{"write", WriteSegment(0x50, []byte{0}), 0},
+		{"read", ReadSegment(0x50, make([]byte, 1)), I2C_M_RD},
+		{"no start", Segment{NoStart: true}, I2C_M_NOSTART},
+		{"ignore nak", Segment{IgnoreNak: true}, I2C_M_IGNORE_NAK},
+		{"ten bit", Segment{TenBit: true}, I2C_M_TEN},
+		{"recv len", Segment{RecvLen: true}, I2C_M_RECV_LEN},
+		{
+			"everything",
+			Segment{Read: true, NoStart: true, IgnoreNak: true, TenBit: true, RecvLen: true},
+			I2C_M_RD | I2C_M_NOSTART | I2C_M_IGNORE_NAK | I2C_M_TEN | I2C_M_RECV_LEN,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.seg.flags(); got != c.want {
+				t.Errorf("flags() = %#x, want %#x", got, c.want)
+			}
+		})
+	}
+}
+
+func TestSegmentRequiredFuncs(t *testing.T) {
+
+	cases := []struct {
+		name string
+		seg  Segment
+		want uint64
+	}{
+		{"plain write", WriteSegment(0x50, []byte{0}), 0},
+		{"plain read", ReadSegment(0x50, make([]byte, 1)), 0},
+		{"no start", Segment{NoStart: true}, I2C_FUNC_NOSTART},
+		{"ignore nak", Segment{IgnoreNak: true}, I2C_FUNC_PROTOCOL_MANGLING},
+		{"ten bit", Segment{TenBit: true}, I2C_FUNC_10BIT_ADDR},
+		{"recv len", Segment{RecvLen: true}, I2C_FUNC_SMBUS_READ_BLOCK_DATA},
+		{
+			"everything",
+			Segment{NoStart: true, IgnoreNak: true, TenBit: true, RecvLen: true},
+			I2C_FUNC_NOSTART | I2C_FUNC_PROTOCOL_MANGLING | I2C_FUNC_10BIT_ADDR | I2C_FUNC_SMBUS_READ_BLOCK_DATA,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := c.seg.requiredFuncs(); got != c.want {
+				t.Errorf("requiredFuncs() = %#x, want %#x", got, c.want)
+			}
+		})
+	}
+}